@@ -6,12 +6,16 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/atomix/go-client/pkg/client/errors"
 	"github.com/onosproject/onos-lib-go/pkg/logging"
 	"github.com/onosproject/onos-mlb/pkg/monitor"
 	"github.com/onosproject/onos-mlb/pkg/southbound/e2control"
+	"github.com/onosproject/onos-mlb/pkg/store/event"
 	"github.com/onosproject/onos-mlb/pkg/store/storage"
+	"github.com/onosproject/onos-mlb/pkg/store/watcher"
 	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"sync"
 	"time"
 )
 
@@ -20,14 +24,79 @@ var log = logging.GetLogger("controller")
 const (
 	RcPreRanParamDefaultOCN = meastype.QOffset0dB
 	OCNDeltaFactor = 3
+
+	// DefaultWorkerPoolSize bounds the number of cells controlled concurrently
+	// on a single tick when the caller does not specify one.
+	DefaultWorkerPoolSize = 8
+
+	// DefaultMinControlInterval is the minimum time that must elapse between
+	// two control decisions for the same sCell when the caller does not
+	// specify one.
+	DefaultMinControlInterval = 0
 )
 
+// thresholdsKey is the sentinel storage.IDs under which the overload/target
+// thresholds are persisted in thresholdStore; thresholds are xApp-wide, not
+// per-cell, but storage.Store only keys on storage.IDs.
+var thresholdsKey = storage.IDs{PlmnID: "_mlb", CellID: "_thresholds"}
+
+// Thresholds is the overload/target pair persisted via thresholdStore.
+type Thresholds struct {
+	OverloadThreshold int
+	TargetThreshold   int
+}
+
+// thresholdsKind tags the VersionedEntry thresholdStore persists Thresholds
+// under, so storage.Restore can decode it back into a Thresholds instead of
+// a bare map[string]interface{}. Thresholds lives here rather than in
+// storage to avoid storage importing controller, so the decoder for it is
+// registered with storage.RegisterKind below instead of built into storage.
+const thresholdsKind storage.Kind = "Thresholds"
+
+func init() {
+	storage.RegisterKind(thresholdsKind,
+		func(value interface{}) ([]byte, error) {
+			return json.Marshal(value)
+		},
+		func(data []byte) (interface{}, error) {
+			var t Thresholds
+			if err := json.Unmarshal(data, &t); err != nil {
+				return nil, err
+			}
+			return t, nil
+		})
+}
+
+// pinKey identifies one sCell/nCell OCN pair that an operator has pinned to
+// a fixed value through the northbound API.
+type pinKey struct {
+	SCell storage.IDs
+	NCell storage.IDs
+}
+
+// pin is an operator-set OCN override that controlLogicEachCell honors in
+// place of the strategy's decision until it expires.
+type pin struct {
+	Ocn    meastype.QOffsetRange
+	Expiry time.Time
+}
+
 func NewHandler(interval int, e2controlHandler e2control.Handler,
 	monitorHandler monitor.Handler,
 	numUEsMeasStore storage.Store,
 	neighborMeasStore storage.Store,
 	statisticsStore storage.Store,
-	ocnStore storage.Store) Handler {
+	ocnStore storage.Store,
+	thresholdStore storage.Store,
+	workerPoolSize int,
+	minControlInterval time.Duration,
+	strategy Strategy) Handler {
+	if workerPoolSize <= 0 {
+		workerPoolSize = DefaultWorkerPoolSize
+	}
+	if strategy == nil {
+		strategy = NewThresholdStrategy()
+	}
 	return &handler{
 		e2controlHandler: e2controlHandler,
 		monitorHandler: monitorHandler,
@@ -35,11 +104,36 @@ func NewHandler(interval int, e2controlHandler e2control.Handler,
 		neighborMeasStore: neighborMeasStore,
 		statisticsStore: statisticsStore,
 		ocnStore: ocnStore,
+		thresholdStore: thresholdStore,
+		interval: interval,
+		workerPoolSize: workerPoolSize,
+		minControlInterval: minControlInterval,
+		strategy: strategy,
+		watchers: watcher.NewWatchers(),
+		inFlight: make(map[storage.IDs]bool),
+		lastControlled: make(map[storage.IDs]time.Time),
+		pinned: make(map[pinKey]pin),
 	}
 }
 
+// Handler runs the MLB control loop and is also the point of contact for the
+// northbound admin API: it owns the live thresholds, the pinned OCN
+// overrides and the event feed that northbound/mlb streams to operators.
 type Handler interface {
+	Run(ctx context.Context) error
+	StartControlLogic(ctx context.Context)
+
+	GetCells(ctx context.Context) ([]storage.IDs, error)
+	GetOcn(ctx context.Context, plmnID string, cellID string) (storage.OcnMap, error)
+	ListOcn(ctx context.Context) (map[storage.IDs]storage.OcnMap, error)
+	SetOcn(ctx context.Context, sPlmnID string, sCellID string, nPlmnID string, nCellID string, ocn meastype.QOffsetRange, ttl time.Duration) error
+	ReplayOcn(ctx context.Context) error
 
+	GetThresholds() Thresholds
+	SetThresholds(ctx context.Context, thresholds Thresholds) error
+	LoadThresholds(ctx context.Context) error
+
+	Watchers() *watcher.Watchers
 }
 
 type handler struct {
@@ -49,17 +143,69 @@ type handler struct {
 	neighborMeasStore storage.Store
 	statisticsStore storage.Store
 	ocnStore storage.Store
+	thresholdStore storage.Store
 
 	interval int
+
+	thresholdsMu sync.RWMutex
 	overloadThreshold int
 	targetThreshold int
+
+	// ocnMu guards read-modify-write access to an sCell's OcnMap: storage.Store
+	// returns the live stored map by reference, so a northbound read
+	// (GetOcn/ListOcn) racing a concurrent applyOcnDelta/updateOcnStore
+	// mutation of that same map is a concurrent map read/write without it.
+	ocnMu sync.RWMutex
+
+	// watchers fans out OCN changes and threshold breaches to northbound
+	// StreamEvents subscribers.
+	watchers *watcher.Watchers
+
+	// pinned holds operator-set OCN overrides from the northbound API;
+	// controlLogicEachCell honors these in place of the strategy's output
+	// until they expire.
+	pinnedMu sync.RWMutex
+	pinned map[pinKey]pin
+
+	// workerPoolSize bounds the number of cells that controlLogicEachCell
+	// is run for concurrently on any given tick.
+	workerPoolSize int
+	// minControlInterval is the minimum time that must elapse between two
+	// control decisions for the same sCell, to avoid stepping OCN for the
+	// same neighbor pair on every tick.
+	minControlInterval time.Duration
+
+	// strategy decides what OCN adjustments to apply for a cell; swappable
+	// at startup so alternative load-balancing algorithms can be selected
+	// without changing the handler.
+	strategy Strategy
+
+	// inFlight tracks the cells whose controlLogicEachCell call has not yet
+	// returned, so a slow cell is skipped rather than re-entered on the next
+	// tick.
+	inFlightMu sync.Mutex
+	inFlight map[storage.IDs]bool
+
+	// lastControlled records when a cell was last handed to
+	// controlLogicEachCell, for minControlInterval enforcement.
+	lastControlledMu sync.Mutex
+	lastControlled map[storage.IDs]time.Time
 }
 
 func (h *handler) Run(ctx context.Context) error {
+	// restore whatever thresholds/OCN were persisted from the previous run
+	// immediately, so a restart doesn't silently revert to the zero-value
+	// thresholds or let the RAN drift on the prior policy until the next tick
+	if err := h.LoadThresholds(ctx); err != nil {
+		log.Error(err)
+	}
+	if err := h.ReplayOcn(ctx); err != nil {
+		log.Error(err)
+	}
+
 	for {
 		select {
 		case <- time.After(time.Duration(h.interval) * time.Second):
-			// ToDo should run as goroutine
 			h.StartControlLogic(ctx)
 		case <- ctx.Done():
 			return nil
@@ -96,14 +242,92 @@ func (h *handler) StartControlLogic(ctx context.Context) {
 		return
 	}
 
-	// run control logic for each cell
+	// run control logic for each cell on a bounded worker pool; a cell still
+	// being processed from a previous tick, or controlled more recently than
+	// minControlInterval, is skipped rather than coalesced or queued
+	sem := make(chan struct{}, h.workerPoolSize)
+	var wg sync.WaitGroup
 	for _, cell := range cells {
-		err = h.controlLogicEachCell(ctx, cell, cells, totalNumUEs)
-		if err != nil {
-			log.Error(err)
-			return
+		cell := cell
+
+		if ctx.Err() != nil {
+			break
 		}
+
+		if !h.tryAcquireCell(cell) {
+			log.Debugf("skipping cell %v; still being controlled from a previous tick", cell)
+			continue
+		}
+
+		if !h.dueForControl(cell) {
+			h.releaseCell(cell)
+			continue
+		}
+
+		wg.Add(1)
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Done()
+			h.releaseCell(cell)
+			continue
+		}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer h.releaseCell(cell)
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err := h.controlLogicEachCell(ctx, cell, cells, totalNumUEs); err != nil {
+				log.Error(err)
+				return
+			}
+			h.markControlled(cell)
+		}()
+	}
+	wg.Wait()
+}
+
+// tryAcquireCell marks a cell as in-flight, returning false if it was already
+// in-flight from a previous tick.
+func (h *handler) tryAcquireCell(ids storage.IDs) bool {
+	h.inFlightMu.Lock()
+	defer h.inFlightMu.Unlock()
+	if h.inFlight[ids] {
+		return false
 	}
+	h.inFlight[ids] = true
+	return true
+}
+
+// releaseCell clears a cell's in-flight marker.
+func (h *handler) releaseCell(ids storage.IDs) {
+	h.inFlightMu.Lock()
+	delete(h.inFlight, ids)
+	h.inFlightMu.Unlock()
+}
+
+// dueForControl reports whether at least minControlInterval has elapsed
+// since the cell was last controlled.
+func (h *handler) dueForControl(ids storage.IDs) bool {
+	if h.minControlInterval <= 0 {
+		return true
+	}
+	h.lastControlledMu.Lock()
+	defer h.lastControlledMu.Unlock()
+	last, ok := h.lastControlled[ids]
+	return !ok || time.Since(last) >= h.minControlInterval
+}
+
+// markControlled records that a cell was just controlled.
+func (h *handler) markControlled(ids storage.IDs) {
+	h.lastControlledMu.Lock()
+	h.lastControlled[ids] = time.Now()
+	h.lastControlledMu.Unlock()
 }
 
 func (h *handler) updateOcnStore(ctx context.Context) error {
@@ -117,38 +341,49 @@ func (h *handler) updateOcnStore(ctx context.Context) error {
 		ids := e.Key
 		neighborList := e.Value.(storage.Neighbors).Value
 
-		if e, err := h.ocnStore.Get(ctx, ids); err != nil {
-			// the new cells connected
-			nOcnMap := make(map[storage.IDs]meastype.QOffsetRange)
-			for _, nIDs := range neighborList {
-				nOcnMap[nIDs] = RcPreRanParamDefaultOCN
-			}
-			_, err = h.ocnStore.Put(ctx, ids, storage.OcnMap{
-				Value: nOcnMap,
-			})
-			if err != nil {
-				return err
-			}
-		} else {
-			nOcnMap := e.Value.(storage.OcnMap).Value
-			// delete removed neighbor
-			for k := range nOcnMap {
-				if !h.containsIDs(k, neighborList) {
-					delete(nOcnMap, k)
-				}
-			}
-			// add new neighbor
-			for _, n := range neighborList {
-				if _, ok := nOcnMap[n]; !ok {
-					nOcnMap[n] = RcPreRanParamDefaultOCN
-				}
-			}
+		if err := h.reconcileOcnMap(ctx, ids, neighborList); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// reconcileOcnMap adds/removes entries in ids' OcnMap to match neighborList,
+// creating the OcnMap at the default OCN if ids has none yet.
+func (h *handler) reconcileOcnMap(ctx context.Context, ids storage.IDs, neighborList []storage.IDs) error {
+	h.ocnMu.Lock()
+	defer h.ocnMu.Unlock()
+
+	value, err := storage.GetVersioned(ctx, h.ocnStore, ids)
+	if err != nil {
+		// the new cells connected
+		nOcnMap := make(map[storage.IDs]meastype.QOffsetRange)
+		for _, nIDs := range neighborList {
+			nOcnMap[nIDs] = RcPreRanParamDefaultOCN
+		}
+		return storage.PutVersioned(ctx, h.ocnStore, ids, storage.KindOcnMap, storage.OcnMap{Value: nOcnMap})
+	}
+
+	nOcnMap := value.(storage.OcnMap).Value
+	// delete removed neighbor
+	for k := range nOcnMap {
+		if !h.containsIDs(k, neighborList) {
+			delete(nOcnMap, k)
+		}
+	}
+	// add new neighbor
+	for _, n := range neighborList {
+		if _, ok := nOcnMap[n]; !ok {
+			nOcnMap[n] = RcPreRanParamDefaultOCN
+		}
+	}
+	// write the reconciled map back - previously dropped on the floor here,
+	// so a neighbor add/remove was lost until the cell's OcnMap happened to
+	// be rewritten for some other reason
+	return storage.PutVersioned(ctx, h.ocnStore, ids, storage.KindOcnMap, storage.OcnMap{Value: nOcnMap})
+}
+
 func (h *handler) containsIDs(ids storage.IDs, idsList []storage.IDs) bool {
 	for _, e := range idsList {
 		if e == ids {
@@ -190,61 +425,290 @@ func (h *handler) controlLogicEachCell(ctx context.Context, ids storage.IDs, cel
 		return err
 	}
 
-	// calculate for each capacity and check sCell's and its neighbors' capacity
-	// if sCell load < target load threshold
-	// reduce Ocn
-	neighborList := neighbors.Value.([]storage.IDs)
+	neighborList := neighbors.Value.(storage.Neighbors).Value
 	numUEsSCell, err := h.numUE(ctx, ids.PlmnID, ids.CellID, cells)
 	if err != nil {
 		return err
 	}
 	capSCell := h.getCapacity(1, totalNumUEs, numUEsSCell)
-	if 1 - capSCell < h.targetThreshold {
-		// send control message to reduce OCn for all neighbors
-		for _, nCellID := range neighborList {
-			entry, err := h.ocnStore.Get(ctx, ids)
-			if err != nil {
-				return err
-			}
-			ocn := entry.Value.(storage.OcnMap).Value[nCellID]
-			if ocn - OCNDeltaFactor < meastype.QOffsetMinus24dB {
-				ocn = meastype.QOffsetMinus24dB
-			} else {
-				ocn = ocn - OCNDeltaFactor
-			}
 
-			h.e2controlHandler.SendControlMessage(ctx, nCellID, ids.NodeID, int32(ocn))
+	neighborLoads := make([]NeighborLoad, 0, len(neighborList))
+	for _, nCellID := range neighborList {
+		numUEsNCell, err := h.numUE(ctx, nCellID.PlmnID, nCellID.CellID, cells)
+		if err != nil {
+			log.Warnf("there is no num(UEs) measurement value; this neighbor (plmnid-%v:cid-%v) may not be controlled by this xAPP; set num(UEs) to 0", nCellID.PlmnID, nCellID.CellID)
 		}
+		neighborLoads = append(neighborLoads, NeighborLoad{
+			IDs:      nCellID,
+			Capacity: h.getCapacity(1, totalNumUEs, numUEsNCell),
+		})
 	}
 
-	// if sCell load > overload threshold && nCell < target load threshold
-	// increase Ocn
-	if 1 - capSCell > h.overloadThreshold {
-		for _, nCellID := range neighborList {
-			numUEsNCell, err := h.numUE(ctx, nCellID.PlmnID, nCellID.CellID, cells)
-			if err != nil {
-				log.Warnf("there is no num(UEs) measurement value; this neighbor (plmnid-%v:cid-%v) may not be controlled by this xAPP; set num(UEs) to 0", nCellID.PlmnID, nCellID.CellID)
-			}
-			capNCell := h.getCapacity(1, totalNumUEs, numUEsNCell)
-			if 1 - capNCell < h.targetThreshold {
-				entry, err := h.ocnStore.Get(ctx, ids)
-				if err != nil {
-					return err
-				}
-				ocn := entry.Value.(storage.OcnMap).Value[nCellID]
-				if ocn + OCNDeltaFactor > meastype.QOffsetMinus24dB {
-					ocn = meastype.QOffset24dB
-				} else {
-					ocn = ocn + OCNDeltaFactor
-				}
-				h.e2controlHandler.SendControlMessage(ctx, nCellID, ids.NodeID, int32(ocn))
-			}
+	thresholds := h.GetThresholds()
+	h.checkThresholdBreach(ids, capSCell, thresholds)
+
+	h.ocnMu.RLock()
+	deltas, err := h.strategy.Decide(ctx, StrategyInput{
+		SCell:             ids,
+		SCellCapacity:     capSCell,
+		Neighbors:         neighborLoads,
+		OverloadThreshold: thresholds.OverloadThreshold,
+		TargetThreshold:   thresholds.TargetThreshold,
+		OcnStore:          h.ocnStore,
+	})
+	h.ocnMu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, delta := range deltas {
+		if err := h.applyOcnDelta(ctx, delta); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// ThresholdBreach is the payload sent over watcher.Watchers when a cell's
+// load crosses the overload or target threshold on a control tick - the
+// same condition the strategies themselves check before deciding on an OCN
+// adjustment, surfaced here purely as a notification for StreamEvents
+// subscribers.
+type ThresholdBreach struct {
+	SCell             storage.IDs
+	Load              int
+	OverloadThreshold int
+	TargetThreshold   int
+}
+
+// checkThresholdBreach sends a ThresholdBreached event if sCell's load,
+// derived from capacity, is on the overload or target side of thresholds.
+func (h *handler) checkThresholdBreach(sCell storage.IDs, capacity int, thresholds Thresholds) {
+	load := 1 - capacity
+	if load <= thresholds.OverloadThreshold && load >= thresholds.TargetThreshold {
+		return
+	}
+	h.watchers.Send(event.Event{
+		Type: event.ThresholdBreached,
+		Key:  sCell,
+		Value: ThresholdBreach{
+			SCell:             sCell,
+			Load:              load,
+			OverloadThreshold: thresholds.OverloadThreshold,
+			TargetThreshold:   thresholds.TargetThreshold,
+		},
+	})
+}
+
+// applyOcnDelta persists a strategy's OCN decision and pushes it down to the
+// RAN. For a pair currently pinned by an operator override, the pinned OCN is
+// applied in place of the decision.
+func (h *handler) applyOcnDelta(ctx context.Context, delta OcnDelta) error {
+	if ocn, ok := h.activePin(delta.SCell, delta.NCell); ok {
+		delta.Ocn = ocn
+	}
+
+	h.ocnMu.Lock()
+	value, err := storage.GetVersioned(ctx, h.ocnStore, delta.SCell)
+	if err != nil {
+		h.ocnMu.Unlock()
+		return err
+	}
+	ocnMap := value.(storage.OcnMap).Value
+	if ocnMap[delta.NCell] == delta.Ocn {
+		h.ocnMu.Unlock()
+		return nil
+	}
+	ocnMap[delta.NCell] = delta.Ocn
+	err = storage.PutVersioned(ctx, h.ocnStore, delta.SCell, storage.KindOcnMap, storage.OcnMap{Value: ocnMap})
+	h.ocnMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	h.e2controlHandler.SendControlMessage(ctx, delta.NCell, delta.SCell.NodeID, int32(delta.Ocn))
+	h.watchers.Send(event.Event{
+		Type:  event.OcnChanged,
+		Key:   delta.SCell,
+		Value: delta,
+	})
+	return nil
+}
+
+// activePin returns the pinned OCN for an sCell/nCell pair and true if an
+// unexpired operator override exists for it.
+func (h *handler) activePin(sCell storage.IDs, nCell storage.IDs) (meastype.QOffsetRange, bool) {
+	h.pinnedMu.RLock()
+	defer h.pinnedMu.RUnlock()
+	p, ok := h.pinned[pinKey{SCell: sCell, NCell: nCell}]
+	if !ok || time.Now().After(p.Expiry) {
+		return 0, false
+	}
+	return p.Ocn, true
+}
+
+// GetCells returns every cell the xApp is currently tracking.
+func (h *handler) GetCells(ctx context.Context) ([]storage.IDs, error) {
+	return h.getCellList(ctx)
+}
+
+// GetOcn returns the OCN map the sCell identified by plmnID/cellID currently
+// applies to its neighbors.
+func (h *handler) GetOcn(ctx context.Context, plmnID string, cellID string) (storage.OcnMap, error) {
+	sCell, err := h.resolveIDs(ctx, plmnID, cellID)
+	if err != nil {
+		return storage.OcnMap{}, err
+	}
+
+	h.ocnMu.RLock()
+	defer h.ocnMu.RUnlock()
+	value, err := storage.GetVersioned(ctx, h.ocnStore, sCell)
+	if err != nil {
+		return storage.OcnMap{}, err
+	}
+	return copyOcnMap(value.(storage.OcnMap)), nil
+}
+
+// copyOcnMap returns a copy of ocnMap safe to hand to a caller outside the
+// ocnMu critical section: storage.Store returns the live stored map by
+// reference, so a caller ranging over it uncopied could race applyOcnDelta
+// mutating that same map on another goroutine.
+func copyOcnMap(ocnMap storage.OcnMap) storage.OcnMap {
+	cp := make(map[storage.IDs]meastype.QOffsetRange, len(ocnMap.Value))
+	for k, v := range ocnMap.Value {
+		cp[k] = v
+	}
+	return storage.OcnMap{Value: cp}
+}
+
+// resolveIDs looks up the full storage.IDs (including the internal NodeID)
+// for a cell identified by its PLMN ID and CGI, the same way
+// controlLogicEachCell does.
+func (h *handler) resolveIDs(ctx context.Context, plmnID string, cellID string) (storage.IDs, error) {
+	cells, err := h.getCellList(ctx)
+	if err != nil {
+		return storage.IDs{}, err
+	}
+	return h.findIDWithCGI(plmnID, cellID, cells)
+}
+
+// ListOcn returns the full OCN matrix, keyed by sCell.
+func (h *handler) ListOcn(ctx context.Context) (map[storage.IDs]storage.OcnMap, error) {
+	h.ocnMu.RLock()
+	defer h.ocnMu.RUnlock()
+
+	result := make(map[storage.IDs]storage.OcnMap)
+	ch := make(chan *storage.Entry)
+	if err := h.ocnStore.ListElements(ctx, ch); err != nil {
+		return nil, err
+	}
+	for e := range ch {
+		if ve, ok := e.Value.(storage.VersionedEntry); ok {
+			result[e.Key] = copyOcnMap(ve.Value.(storage.OcnMap))
+			continue
+		}
+		result[e.Key] = copyOcnMap(e.Value.(storage.OcnMap))
+	}
+	return result, nil
+}
+
+// SetOcn pins the OCN the sCell identified by sPlmnID/sCellID applies
+// towards the neighbor identified by nPlmnID/nCellID to ocn for ttl,
+// overriding whatever the strategy would otherwise decide. A ttl <= 0
+// clears any existing pin for the pair instead of setting one.
+func (h *handler) SetOcn(ctx context.Context, sPlmnID string, sCellID string, nPlmnID string, nCellID string, ocn meastype.QOffsetRange, ttl time.Duration) error {
+	sCell, err := h.resolveIDs(ctx, sPlmnID, sCellID)
+	if err != nil {
+		return err
+	}
+	nCell, err := h.resolveIDs(ctx, nPlmnID, nCellID)
+	if err != nil {
+		return err
+	}
+
+	key := pinKey{SCell: sCell, NCell: nCell}
+
+	h.pinnedMu.Lock()
+	if ttl <= 0 {
+		delete(h.pinned, key)
+	} else {
+		h.pinned[key] = pin{Ocn: ocn, Expiry: time.Now().Add(ttl)}
+	}
+	h.pinnedMu.Unlock()
+
+	if ttl <= 0 {
+		return nil
+	}
+	return h.applyOcnDelta(ctx, OcnDelta{SCell: sCell, NCell: nCell, Ocn: ocn})
+}
+
+// ReplayOcn pushes the currently persisted OCN matrix down to the RAN via
+// e2controlHandler, so a freshly restarted xApp re-asserts its policy
+// immediately instead of drifting until the next control tick picks it up.
+func (h *handler) ReplayOcn(ctx context.Context) error {
+	matrix, err := h.ListOcn(ctx)
+	if err != nil {
+		return err
+	}
+	for sCell, ocnMap := range matrix {
+		for nCell, ocn := range ocnMap.Value {
+			h.e2controlHandler.SendControlMessage(ctx, nCell, sCell.NodeID, int32(ocn))
+		}
+	}
+	return nil
+}
+
+// GetThresholds returns the thresholds currently in effect.
+func (h *handler) GetThresholds() Thresholds {
+	h.thresholdsMu.RLock()
+	defer h.thresholdsMu.RUnlock()
+	return Thresholds{OverloadThreshold: h.overloadThreshold, TargetThreshold: h.targetThreshold}
+}
+
+// SetThresholds updates the overload/target thresholds in effect and
+// persists them so a restart does not revert to the zero-value defaults.
+func (h *handler) SetThresholds(ctx context.Context, thresholds Thresholds) error {
+	if err := storage.PutVersioned(ctx, h.thresholdStore, thresholdsKey, thresholdsKind, thresholds); err != nil {
+		return err
+	}
+
+	h.thresholdsMu.Lock()
+	h.overloadThreshold = thresholds.OverloadThreshold
+	h.targetThreshold = thresholds.TargetThreshold
+	h.thresholdsMu.Unlock()
+
+	h.watchers.Send(event.Event{
+		Type:  event.ThresholdsChanged,
+		Key:   thresholdsKey,
+		Value: thresholds,
+	})
+	return nil
+}
+
+// LoadThresholds restores the thresholds persisted in thresholdStore, if
+// any; Run calls it once on startup, before its first control tick.
+func (h *handler) LoadThresholds(ctx context.Context) error {
+	value, err := storage.GetVersioned(ctx, h.thresholdStore, thresholdsKey)
+	if err != nil {
+		// nothing persisted yet; keep the zero-value thresholds
+		return nil
+	}
+
+	thresholds := value.(Thresholds)
+	h.thresholdsMu.Lock()
+	h.overloadThreshold = thresholds.OverloadThreshold
+	h.targetThreshold = thresholds.TargetThreshold
+	h.thresholdsMu.Unlock()
+	return nil
+}
+
+// Watchers exposes the event feed northbound/mlb's StreamEvents subscribes
+// to.
+func (h *handler) Watchers() *watcher.Watchers {
+	return h.watchers
+}
+
 func (h *handler) getCapacity(denominationFactor float64, totalNumUEs int, numUEs int) int {
 	capacity := (1 - float64(numUEs) / (denominationFactor * float64(totalNumUEs))) * 100
 	return int(capacity)
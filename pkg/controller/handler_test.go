@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"github.com/onosproject/onos-mlb/pkg/store/storage"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeMonitor struct{}
+
+func (fakeMonitor) Monitor(ctx context.Context) error { return nil }
+
+// fakeE2Control counts SendControlMessage calls per nCell and tracks the
+// peak number of concurrent callers (via a configurable per-call delay), so
+// a test can assert the worker pool actually bounds concurrency and that no
+// cell is controlled more than once per tick.
+type fakeE2Control struct {
+	mu    sync.Mutex
+	calls map[storage.IDs]int
+	delay time.Duration
+
+	inFlight int32
+	peak     int32
+}
+
+func newFakeE2Control(delay time.Duration) *fakeE2Control {
+	return &fakeE2Control{calls: make(map[storage.IDs]int), delay: delay}
+}
+
+func (f *fakeE2Control) SendControlMessage(ctx context.Context, nCell storage.IDs, nodeID string, ocn int32) {
+	cur := atomic.AddInt32(&f.inFlight, 1)
+	for {
+		peak := atomic.LoadInt32(&f.peak)
+		if cur <= peak || atomic.CompareAndSwapInt32(&f.peak, peak, cur) {
+			break
+		}
+	}
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	atomic.AddInt32(&f.inFlight, -1)
+
+	f.mu.Lock()
+	f.calls[nCell]++
+	f.mu.Unlock()
+}
+
+func (f *fakeE2Control) callCounts() map[storage.IDs]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make(map[storage.IDs]int, len(f.calls))
+	for k, v := range f.calls {
+		out[k] = v
+	}
+	return out
+}
+
+// newTestHandler wires up a handler over in-memory stores with cellCount
+// sCells, each with one neighbor at the default OCN. Thresholds are set so
+// ThresholdStrategy's "under target" branch fires unconditionally for every
+// cell, guaranteeing SendControlMessage is exercised - letting these tests
+// observe the worker pool/in-flight bookkeeping around controlLogicEachCell
+// rather than depending on strategy math.
+func newTestHandler(t *testing.T, workerPoolSize int, minControlInterval time.Duration, e2 *fakeE2Control, cellCount int) (*handler, []storage.IDs) {
+	t.Helper()
+	ctx := context.Background()
+
+	numUEsStore := storage.NewStore()
+	neighborStore := storage.NewStore()
+	ocnStore := storage.NewStore()
+	thresholdStore := storage.NewStore()
+
+	cells := make([]storage.IDs, cellCount)
+	for i := 0; i < cellCount; i++ {
+		sCell := storage.IDs{PlmnID: "1", CellID: fmt.Sprintf("s%d", i), NodeID: fmt.Sprintf("node%d", i)}
+		nCell := storage.IDs{PlmnID: "1", CellID: fmt.Sprintf("n%d", i), NodeID: fmt.Sprintf("nnode%d", i)}
+		cells[i] = sCell
+
+		if _, err := numUEsStore.Put(ctx, sCell, 10); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := numUEsStore.Put(ctx, nCell, 10); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := neighborStore.Put(ctx, sCell, storage.Neighbors{Value: []storage.IDs{nCell}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.PutVersioned(ctx, ocnStore, sCell, storage.KindOcnMap, storage.OcnMap{
+			Value: map[storage.IDs]meastype.QOffsetRange{nCell: RcPreRanParamDefaultOCN},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHandler(1, e2, fakeMonitor{}, numUEsStore, neighborStore, storage.NewStore(), ocnStore, thresholdStore,
+		workerPoolSize, minControlInterval, NewThresholdStrategy()).(*handler)
+	if err := h.SetThresholds(ctx, Thresholds{OverloadThreshold: 1000, TargetThreshold: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	return h, cells
+}
+
+// TestStartControlLogicBoundsConcurrency asserts controlLogicEachCell never
+// runs more than workerPoolSize instances concurrently, even with far more
+// cells than pool slots and a per-call delay that would otherwise let every
+// cell run at once.
+func TestStartControlLogicBoundsConcurrency(t *testing.T) {
+	const workerPoolSize = 3
+	const cellCount = 20
+
+	e2 := newFakeE2Control(5 * time.Millisecond)
+	h, _ := newTestHandler(t, workerPoolSize, 0, e2, cellCount)
+
+	h.StartControlLogic(context.Background())
+
+	if e2.peak > workerPoolSize {
+		t.Fatalf("expected at most %d concurrent controlLogicEachCell calls, observed %d", workerPoolSize, e2.peak)
+	}
+	if e2.peak == 0 {
+		t.Fatalf("test is not exercising any concurrency; peak observed was 0")
+	}
+}
+
+// TestStartControlLogicSkipsInFlightCell asserts a cell whose
+// controlLogicEachCell call from a previous tick has not returned yet is
+// skipped by a concurrently running tick, rather than re-entered - i.e. no
+// duplicate SendControlMessage calls for the same cell within one overlap.
+func TestStartControlLogicSkipsInFlightCell(t *testing.T) {
+	const cellCount = 5
+
+	e2 := newFakeE2Control(50 * time.Millisecond)
+	h, cells := newTestHandler(t, cellCount, 0, e2, cellCount)
+
+	var wg sync.WaitGroup
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.StartControlLogic(ctx)
+		}()
+		// give the first tick time to mark every cell in-flight before the
+		// second tick starts, so the second tick's tryAcquireCell calls are
+		// guaranteed to race against cells still being controlled
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	counts := e2.callCounts()
+	for _, sCell := range cells {
+		nCell := storage.IDs{PlmnID: sCell.PlmnID, CellID: "n" + sCell.CellID[1:], NodeID: "n" + sCell.NodeID}
+		if counts[nCell] > 1 {
+			t.Fatalf("cell %v controlled %d times across two overlapping ticks; expected the in-flight one skipped", sCell, counts[nCell])
+		}
+	}
+
+	h.inFlightMu.Lock()
+	leaked := len(h.inFlight)
+	h.inFlightMu.Unlock()
+	if leaked != 0 {
+		t.Fatalf("expected inFlight to be empty once both ticks finished, got %d entries", leaked)
+	}
+}
+
+// TestStartControlLogicHonorsMinControlInterval asserts a cell controlled on
+// one tick is not re-marked as controlled by a tick that follows within
+// minControlInterval.
+func TestStartControlLogicHonorsMinControlInterval(t *testing.T) {
+	const cellCount = 3
+
+	e2 := newFakeE2Control(0)
+	h, cells := newTestHandler(t, cellCount, time.Hour, e2, cellCount)
+
+	ctx := context.Background()
+	h.StartControlLogic(ctx)
+
+	h.lastControlledMu.Lock()
+	controlledCount := len(h.lastControlled)
+	firstTick := make(map[storage.IDs]time.Time, controlledCount)
+	for k, v := range h.lastControlled {
+		firstTick[k] = v
+	}
+	h.lastControlledMu.Unlock()
+	if controlledCount != cellCount {
+		t.Fatalf("expected all %d cells marked controlled after the first tick, got %d", cellCount, controlledCount)
+	}
+
+	h.StartControlLogic(ctx)
+
+	h.lastControlledMu.Lock()
+	defer h.lastControlledMu.Unlock()
+	for _, sCell := range cells {
+		if !h.lastControlled[sCell].Equal(firstTick[sCell]) {
+			t.Fatalf("cell %v re-marked controlled by a tick within minControlInterval", sCell)
+		}
+	}
+}
+
+// TestGetOcnListOcnRaceAgainstSetOcn hammers GetOcn/ListOcn reads against
+// concurrent SetOcn writes for the same sCell/nCell pair, under -race. It
+// would previously fatal with "concurrent map read and map write" /
+// "concurrent map writes", since storage.Store.Get returns the live stored
+// map by reference and applyOcnDelta mutates it in place; GetOcn/ListOcn now
+// read under ocnMu and return copies.
+func TestGetOcnListOcnRaceAgainstSetOcn(t *testing.T) {
+	e2 := newFakeE2Control(0)
+	h, cells := newTestHandler(t, 4, 0, e2, 1)
+	sCell := cells[0]
+	nCell := storage.IDs{PlmnID: sCell.PlmnID, CellID: "n" + sCell.CellID[1:], NodeID: "n" + sCell.NodeID}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if ocnMap, err := h.GetOcn(ctx, sCell.PlmnID, sCell.CellID); err == nil {
+					for range ocnMap.Value {
+					}
+				}
+				if matrix, err := h.ListOcn(ctx); err == nil {
+					for _, ocnMap := range matrix {
+						for range ocnMap.Value {
+						}
+					}
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_ = h.SetOcn(ctx, sCell.PlmnID, sCell.CellID, nCell.PlmnID, nCell.CellID,
+					meastype.QOffsetRange(i), time.Millisecond)
+			}
+		}(i)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
+
+// TestSnapshotRestoreThresholds asserts a thresholdStore survives a
+// Snapshot/Restore round-trip with its Thresholds value and concrete type
+// intact, the same way LoadThresholds expects to read it back on restart.
+func TestSnapshotRestoreThresholds(t *testing.T) {
+	ctx := context.Background()
+	thresholdStore := storage.NewStore()
+
+	want := Thresholds{OverloadThreshold: 70, TargetThreshold: 20}
+	if err := storage.PutVersioned(ctx, thresholdStore, thresholdsKey, thresholdsKind, want); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := storage.Snapshot(ctx, thresholdStore, thresholdsKind)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := storage.NewStore()
+	if err := storage.Restore(ctx, restored, r); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := storage.GetVersioned(ctx, restored, thresholdsKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := value.(Thresholds)
+	if !ok {
+		t.Fatalf("expected restored value to decode as Thresholds, got %T", value)
+	}
+	if got != want {
+		t.Fatalf("expected restored thresholds %+v, got %+v", want, got)
+	}
+}
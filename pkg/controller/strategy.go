@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package controller
+
+import (
+	"context"
+	"github.com/onosproject/onos-mlb/pkg/store/storage"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"sort"
+)
+
+// UtilityMaxTopK is the default number of least-loaded neighbors the
+// utility-max strategy bumps OCN for on each overloaded sCell.
+const UtilityMaxTopK = 1
+
+// NeighborLoad is the capacity of a single neighbor cell, as seen by the
+// sCell currently being controlled.
+type NeighborLoad struct {
+	IDs      storage.IDs
+	Capacity int
+}
+
+// StrategyInput carries everything a Strategy needs to decide on OCN
+// adjustments for a single sCell, without the strategy having to know how
+// capacities were computed or how deltas are applied.
+type StrategyInput struct {
+	SCell             storage.IDs
+	SCellCapacity     int
+	Neighbors         []NeighborLoad
+	OverloadThreshold int
+	TargetThreshold   int
+
+	// OcnStore is a read-only handle on the current OCN matrix, so a
+	// strategy can enforce per-pair rate limits or anti-oscillation rules
+	// against the previously applied values. Strategies must not mutate it;
+	// the handler persists whatever deltas are returned.
+	OcnStore storage.Store
+}
+
+// OcnDelta is one OCN adjustment a Strategy wants applied from SCell towards
+// NCell.
+type OcnDelta struct {
+	SCell storage.IDs
+	NCell storage.IDs
+	Ocn   meastype.QOffsetRange
+}
+
+// Strategy decides what OCN adjustments, if any, should be applied for a
+// single sCell on a control tick. Implementations are plugged in at startup
+// via NewHandler and are expected to be stateless or internally synchronized,
+// since they may be invoked for several cells concurrently.
+type Strategy interface {
+	Decide(ctx context.Context, in StrategyInput) ([]OcnDelta, error)
+}
+
+func currentOcn(ctx context.Context, ocnStore storage.Store, sCell storage.IDs, nCell storage.IDs) (meastype.QOffsetRange, error) {
+	value, err := storage.GetVersioned(ctx, ocnStore, sCell)
+	if err != nil {
+		return RcPreRanParamDefaultOCN, err
+	}
+	return value.(storage.OcnMap).Value[nCell], nil
+}
+
+func clampOcn(ocn meastype.QOffsetRange) meastype.QOffsetRange {
+	if ocn < meastype.QOffsetMinus24dB {
+		return meastype.QOffsetMinus24dB
+	}
+	if ocn > meastype.QOffset24dB {
+		return meastype.QOffset24dB
+	}
+	return ocn
+}
+
+// dampenReversal is the per-pair anti-oscillation rule every strategy below
+// applies via OcnStore: a single tick must not reverse a pair's OCN across
+// its neutral default (RcPreRanParamDefaultOCN). Such a pair settles back at
+// the default first; only a later tick is free to push it further the other
+// way.
+func dampenReversal(current meastype.QOffsetRange, proposed meastype.QOffsetRange) meastype.QOffsetRange {
+	if current > RcPreRanParamDefaultOCN && proposed < RcPreRanParamDefaultOCN {
+		return RcPreRanParamDefaultOCN
+	}
+	if current < RcPreRanParamDefaultOCN && proposed > RcPreRanParamDefaultOCN {
+		return RcPreRanParamDefaultOCN
+	}
+	return proposed
+}
+
+// ThresholdStrategy is the original hysteresis rule: a fixed +/-
+// OCNDeltaFactor step, clamped at +/-24 dB, whenever the sCell load crosses
+// the overload or target threshold.
+type ThresholdStrategy struct{}
+
+// NewThresholdStrategy returns the default fixed-step hysteresis strategy.
+func NewThresholdStrategy() *ThresholdStrategy {
+	return &ThresholdStrategy{}
+}
+
+func (s *ThresholdStrategy) Decide(ctx context.Context, in StrategyInput) ([]OcnDelta, error) {
+	deltas := make([]OcnDelta, 0)
+
+	if 1-in.SCellCapacity < in.TargetThreshold {
+		for _, n := range in.Neighbors {
+			ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn-OCNDeltaFactor))})
+		}
+		return deltas, nil
+	}
+
+	if 1-in.SCellCapacity > in.OverloadThreshold {
+		for _, n := range in.Neighbors {
+			if 1-n.Capacity >= in.TargetThreshold {
+				continue
+			}
+			ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn+OCNDeltaFactor))})
+		}
+	}
+
+	return deltas, nil
+}
+
+// ProportionalStrategy scales the OCN step by how far the sCell load is from
+// the target load, instead of always stepping by OCNDeltaFactor.
+type ProportionalStrategy struct{}
+
+// NewProportionalStrategy returns a strategy whose OCN step size is
+// proportional to (sCellLoad - targetLoad), in 0.5 dB units, clamped to
+// [-24, 24] dB.
+func NewProportionalStrategy() *ProportionalStrategy {
+	return &ProportionalStrategy{}
+}
+
+func (s *ProportionalStrategy) Decide(ctx context.Context, in StrategyInput) ([]OcnDelta, error) {
+	sCellLoad := 1 - in.SCellCapacity
+	deltas := make([]OcnDelta, 0)
+
+	if sCellLoad < in.TargetThreshold {
+		step := s.step(sCellLoad, in.TargetThreshold)
+		for _, n := range in.Neighbors {
+			ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn-step))})
+		}
+		return deltas, nil
+	}
+
+	if sCellLoad > in.OverloadThreshold {
+		step := s.step(sCellLoad, in.TargetThreshold)
+		for _, n := range in.Neighbors {
+			if 1-n.Capacity >= in.TargetThreshold {
+				continue
+			}
+			ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn+step))})
+		}
+	}
+
+	return deltas, nil
+}
+
+// step converts a raw load delta into an OCN step in 0.5 dB units.
+func (s *ProportionalStrategy) step(load int, targetLoad int) meastype.QOffsetRange {
+	diff := load - targetLoad
+	if diff < 0 {
+		diff = -diff
+	}
+	step := meastype.QOffsetRange(diff) / 2
+	if step < OCNDeltaFactor {
+		step = OCNDeltaFactor
+	}
+	return clampOcn(step)
+}
+
+// UtilityMaxStrategy ranks neighbors of an overloaded sCell by how much
+// headroom they have below the target load and only bumps OCN for the
+// top-k least-loaded ones, so traffic is not stampeded onto a single
+// neighbor.
+type UtilityMaxStrategy struct {
+	topK int
+}
+
+// NewUtilityMaxStrategy returns a strategy that steers traffic onto at most
+// topK neighbors per tick. A topK <= 0 falls back to UtilityMaxTopK.
+func NewUtilityMaxStrategy(topK int) *UtilityMaxStrategy {
+	if topK <= 0 {
+		topK = UtilityMaxTopK
+	}
+	return &UtilityMaxStrategy{topK: topK}
+}
+
+func (s *UtilityMaxStrategy) Decide(ctx context.Context, in StrategyInput) ([]OcnDelta, error) {
+	sCellLoad := 1 - in.SCellCapacity
+	deltas := make([]OcnDelta, 0)
+
+	if sCellLoad < in.TargetThreshold {
+		for _, n := range in.Neighbors {
+			ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+			if err != nil {
+				return nil, err
+			}
+			deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn-OCNDeltaFactor))})
+		}
+		return deltas, nil
+	}
+
+	if sCellLoad <= in.OverloadThreshold {
+		return deltas, nil
+	}
+
+	candidates := make([]NeighborLoad, 0, len(in.Neighbors))
+	for _, n := range in.Neighbors {
+		if 1-n.Capacity < in.TargetThreshold {
+			candidates = append(candidates, n)
+		}
+	}
+	// rank by utility = targetLoad - nCellLoad, descending: the neighbor
+	// with the most headroom below target is offloaded to first
+	sort.Slice(candidates, func(i, j int) bool {
+		utilI := in.TargetThreshold - (1 - candidates[i].Capacity)
+		utilJ := in.TargetThreshold - (1 - candidates[j].Capacity)
+		return utilI > utilJ
+	})
+
+	k := s.topK
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+	for _, n := range candidates[:k] {
+		ocn, err := currentOcn(ctx, in.OcnStore, in.SCell, n.IDs)
+		if err != nil {
+			return nil, err
+		}
+		deltas = append(deltas, OcnDelta{SCell: in.SCell, NCell: n.IDs, Ocn: clampOcn(dampenReversal(ocn, ocn+OCNDeltaFactor))})
+	}
+
+	return deltas, nil
+}
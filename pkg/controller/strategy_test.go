@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package controller
+
+import (
+	"context"
+	"github.com/onosproject/onos-mlb/pkg/store/storage"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"testing"
+)
+
+var (
+	sCell = storage.IDs{PlmnID: "1", CellID: "s0", NodeID: "node0"}
+	nCell = storage.IDs{PlmnID: "1", CellID: "n0", NodeID: "nnode0"}
+)
+
+// newOcnStore returns an OcnStore with sCell's only neighbor, nCell, seeded
+// at ocn.
+func newOcnStore(t *testing.T, ocn meastype.QOffsetRange) storage.Store {
+	t.Helper()
+	store := storage.NewStore()
+	if err := storage.PutVersioned(context.Background(), store, sCell, storage.KindOcnMap, storage.OcnMap{
+		Value: map[storage.IDs]meastype.QOffsetRange{nCell: ocn},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func deltaFor(t *testing.T, deltas []OcnDelta, nCell storage.IDs) OcnDelta {
+	t.Helper()
+	for _, d := range deltas {
+		if d.NCell == nCell {
+			return d
+		}
+	}
+	t.Fatalf("no delta for neighbor %v among %v", nCell, deltas)
+	return OcnDelta{}
+}
+
+func TestThresholdStrategyDecide(t *testing.T) {
+	s := NewThresholdStrategy()
+	ctx := context.Background()
+
+	t.Run("under target threshold steps down", func(t *testing.T) {
+		deltas, err := s.Decide(ctx, StrategyInput{
+			SCell:             sCell,
+			SCellCapacity:     90,
+			Neighbors:         []NeighborLoad{{IDs: nCell, Capacity: 50}},
+			OverloadThreshold: 70,
+			TargetThreshold:   20,
+			OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := deltaFor(t, deltas, nCell)
+		if got.Ocn != RcPreRanParamDefaultOCN-OCNDeltaFactor {
+			t.Fatalf("expected ocn stepped down by OCNDeltaFactor, got %v", got.Ocn)
+		}
+	})
+
+	t.Run("over overload threshold steps up underloaded neighbors only", func(t *testing.T) {
+		deltas, err := s.Decide(ctx, StrategyInput{
+			SCell:         sCell,
+			SCellCapacity: -60, // sCellLoad = 61, over the overload threshold
+			Neighbors: []NeighborLoad{
+				{IDs: nCell, Capacity: 90}, // nCellLoad = -89, well under target - eligible
+				{IDs: storage.IDs{PlmnID: "1", CellID: "n1", NodeID: "nnode1"}, Capacity: -30}, // nCellLoad = 31, already at/over target - skipped
+			},
+			OverloadThreshold: 50,
+			TargetThreshold:   20,
+			OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deltas) != 1 {
+			t.Fatalf("expected exactly 1 delta for the underloaded neighbor, got %d", len(deltas))
+		}
+		if deltas[0].NCell != nCell {
+			t.Fatalf("expected the underloaded neighbor %v to be stepped, got %v", nCell, deltas[0].NCell)
+		}
+		if deltas[0].Ocn != RcPreRanParamDefaultOCN+OCNDeltaFactor {
+			t.Fatalf("expected ocn stepped up by OCNDeltaFactor, got %v", deltas[0].Ocn)
+		}
+	})
+
+	t.Run("between thresholds makes no change", func(t *testing.T) {
+		deltas, err := s.Decide(ctx, StrategyInput{
+			SCell:             sCell,
+			SCellCapacity:     -39, // sCellLoad = 40, strictly between target and overload
+			Neighbors:         []NeighborLoad{{IDs: nCell, Capacity: 50}},
+			OverloadThreshold: 70,
+			TargetThreshold:   20,
+			OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(deltas) != 0 {
+			t.Fatalf("expected no deltas between thresholds, got %v", deltas)
+		}
+	})
+
+	t.Run("rejects a reversal across the neutral default", func(t *testing.T) {
+		// current is just above default; stepping down by the full
+		// OCNDeltaFactor would cross past default to the other side
+		deltas, err := s.Decide(ctx, StrategyInput{
+			SCell:             sCell,
+			SCellCapacity:     90,
+			Neighbors:         []NeighborLoad{{IDs: nCell, Capacity: 50}},
+			OverloadThreshold: 70,
+			TargetThreshold:   20,
+			OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN+1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := deltaFor(t, deltas, nCell)
+		if got.Ocn != RcPreRanParamDefaultOCN {
+			t.Fatalf("expected dampenReversal to settle at the default instead of reversing past it, got %v", got.Ocn)
+		}
+	})
+}
+
+func TestProportionalStrategyDecide(t *testing.T) {
+	s := NewProportionalStrategy()
+	ctx := context.Background()
+
+	// sCellLoad = 1 - (-98) = 99, diff from target(20) = 79, step = 79/2 = 39,
+	// clamped to the +24dB ceiling
+	deltas, err := s.Decide(ctx, StrategyInput{
+		SCell:             sCell,
+		SCellCapacity:     -98,
+		Neighbors:         []NeighborLoad{{IDs: nCell, Capacity: 50}},
+		OverloadThreshold: 50,
+		TargetThreshold:   20,
+		OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := deltaFor(t, deltas, nCell)
+	if got.Ocn != meastype.QOffset24dB {
+		t.Fatalf("expected a step proportional to the load/target gap clamped to +24dB, got %v", got.Ocn)
+	}
+
+	t.Run("step never falls below OCNDeltaFactor", func(t *testing.T) {
+		// sCellLoad = 1 - (-18) = 19, just 1 below target(20) - too small a
+		// gap for a proportional step to clear the OCNDeltaFactor floor
+		deltas, err := s.Decide(ctx, StrategyInput{
+			SCell:             sCell,
+			SCellCapacity:     -18,
+			Neighbors:         []NeighborLoad{{IDs: nCell, Capacity: 50}},
+			OverloadThreshold: 50,
+			TargetThreshold:   20,
+			OcnStore:          newOcnStore(t, RcPreRanParamDefaultOCN),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := deltaFor(t, deltas, nCell)
+		if got.Ocn != RcPreRanParamDefaultOCN-OCNDeltaFactor {
+			t.Fatalf("expected the minimum OCNDeltaFactor step when load is barely under target, got %v", got.Ocn)
+		}
+	})
+}
+
+func TestUtilityMaxStrategyDecide(t *testing.T) {
+	n0 := storage.IDs{PlmnID: "1", CellID: "n0", NodeID: "nnode0"}
+	n1 := storage.IDs{PlmnID: "1", CellID: "n1", NodeID: "nnode1"}
+	n2 := storage.IDs{PlmnID: "1", CellID: "n2", NodeID: "nnode2"}
+
+	store := storage.NewStore()
+	if err := storage.PutVersioned(context.Background(), store, sCell, storage.KindOcnMap, storage.OcnMap{
+		Value: map[storage.IDs]meastype.QOffsetRange{
+			n0: RcPreRanParamDefaultOCN,
+			n1: RcPreRanParamDefaultOCN,
+			n2: RcPreRanParamDefaultOCN,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewUtilityMaxStrategy(2)
+	deltas, err := s.Decide(context.Background(), StrategyInput{
+		SCell:         sCell,
+		SCellCapacity: -60, // sCellLoad = 61, over the overload threshold
+		Neighbors: []NeighborLoad{
+			{IDs: n0, Capacity: 90},  // nCellLoad = -89, most headroom below target - picked first
+			{IDs: n1, Capacity: 85},  // nCellLoad = -84, picked second
+			{IDs: n2, Capacity: -20}, // nCellLoad = 21, already at/over target - not a candidate
+		},
+		OverloadThreshold: 50,
+		TargetThreshold:   20,
+		OcnStore:          store,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltas) != 2 {
+		t.Fatalf("expected only topK=2 neighbors bumped, got %d: %v", len(deltas), deltas)
+	}
+	bumped := map[storage.IDs]bool{deltas[0].NCell: true, deltas[1].NCell: true}
+	if !bumped[n0] || !bumped[n1] {
+		t.Fatalf("expected the two least-loaded neighbors (n0, n1) bumped, got %v", deltas)
+	}
+	if bumped[n2] {
+		t.Fatalf("expected n2, already past target load, not to be stampeded onto, got %v", deltas)
+	}
+}
+
+func TestDampenReversal(t *testing.T) {
+	cases := []struct {
+		name     string
+		current  meastype.QOffsetRange
+		proposed meastype.QOffsetRange
+		want     meastype.QOffsetRange
+	}{
+		{"same direction is unaffected", RcPreRanParamDefaultOCN + OCNDeltaFactor, RcPreRanParamDefaultOCN + 2*OCNDeltaFactor, RcPreRanParamDefaultOCN + 2*OCNDeltaFactor},
+		{"positive to negative settles at default", RcPreRanParamDefaultOCN + OCNDeltaFactor, RcPreRanParamDefaultOCN - OCNDeltaFactor, RcPreRanParamDefaultOCN},
+		{"negative to positive settles at default", RcPreRanParamDefaultOCN - OCNDeltaFactor, RcPreRanParamDefaultOCN + OCNDeltaFactor, RcPreRanParamDefaultOCN},
+		{"starting at default is unaffected", RcPreRanParamDefaultOCN, RcPreRanParamDefaultOCN + OCNDeltaFactor, RcPreRanParamDefaultOCN + OCNDeltaFactor},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := dampenReversal(c.current, c.proposed); got != c.want {
+				t.Fatalf("dampenReversal(%v, %v) = %v, want %v", c.current, c.proposed, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+// Package mlb implements the northbound gRPC admin API defined in
+// api/mlb/v1/mlb.proto, for inspecting and overriding MLB xApp state at
+// runtime.
+package mlb
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	mlbapi "github.com/onosproject/onos-mlb/api/mlb/v1"
+	"github.com/onosproject/onos-mlb/pkg/controller"
+	"github.com/onosproject/onos-mlb/pkg/store/event"
+	"github.com/onosproject/onos-mlb/pkg/store/storage"
+	"github.com/onosproject/onos-mlb/pkg/store/watcher"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"google.golang.org/grpc"
+	"time"
+)
+
+var log = logging.GetLogger("northbound/mlb")
+
+// streamEventsBufferSize bounds how many undelivered events StreamEvents
+// will buffer for a subscriber before dropping the oldest one.
+const streamEventsBufferSize = 64
+
+// Service implements the MlbService northbound gRPC API on top of a
+// controller.Handler.
+type Service struct {
+	mlbapi.UnimplementedMlbServiceServer
+	handler controller.Handler
+}
+
+// NewService returns a northbound Service backed by handler.
+func NewService(handler controller.Handler) *Service {
+	return &Service{handler: handler}
+}
+
+// Register registers the service on r, per the onos-lib-go
+// northbound.Service convention.
+func (s *Service) Register(r *grpc.Server) {
+	mlbapi.RegisterMlbServiceServer(r, s)
+}
+
+func toCellID(ids storage.IDs) *mlbapi.CellID {
+	return &mlbapi.CellID{PlmnId: ids.PlmnID, CellId: ids.CellID}
+}
+
+func (s *Service) GetCells(ctx context.Context, req *mlbapi.GetCellsRequest) (*mlbapi.GetCellsResponse, error) {
+	cells, err := s.handler.GetCells(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mlbapi.GetCellsResponse{Cells: make([]*mlbapi.CellID, 0, len(cells))}
+	for _, cell := range cells {
+		resp.Cells = append(resp.Cells, toCellID(cell))
+	}
+	return resp, nil
+}
+
+func (s *Service) GetOcn(ctx context.Context, req *mlbapi.GetOcnRequest) (*mlbapi.GetOcnResponse, error) {
+	ocnMap, err := s.handler.GetOcn(ctx, req.GetSCell().GetPlmnId(), req.GetSCell().GetCellId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mlbapi.GetOcnResponse{Entries: make([]*mlbapi.OcnEntry, 0, len(ocnMap.Value))}
+	for nCell, ocn := range ocnMap.Value {
+		resp.Entries = append(resp.Entries, &mlbapi.OcnEntry{NCell: toCellID(nCell), OcnDb: int32(ocn)})
+	}
+	return resp, nil
+}
+
+func (s *Service) ListOcn(ctx context.Context, req *mlbapi.ListOcnRequest) (*mlbapi.ListOcnResponse, error) {
+	matrix, err := s.handler.ListOcn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &mlbapi.ListOcnResponse{SCells: make([]*mlbapi.SCellOcn, 0, len(matrix))}
+	for sCell, ocnMap := range matrix {
+		entries := make([]*mlbapi.OcnEntry, 0, len(ocnMap.Value))
+		for nCell, ocn := range ocnMap.Value {
+			entries = append(entries, &mlbapi.OcnEntry{NCell: toCellID(nCell), OcnDb: int32(ocn)})
+		}
+		resp.SCells = append(resp.SCells, &mlbapi.SCellOcn{SCell: toCellID(sCell), Entries: entries})
+	}
+	return resp, nil
+}
+
+func (s *Service) SetOcn(ctx context.Context, req *mlbapi.SetOcnRequest) (*mlbapi.SetOcnResponse, error) {
+	ttl := time.Duration(req.GetTtlSeconds()) * time.Second
+	err := s.handler.SetOcn(ctx,
+		req.GetSCell().GetPlmnId(), req.GetSCell().GetCellId(),
+		req.GetNCell().GetPlmnId(), req.GetNCell().GetCellId(),
+		meastype.QOffsetRange(req.GetOcnDb()), ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &mlbapi.SetOcnResponse{}, nil
+}
+
+func (s *Service) GetThresholds(ctx context.Context, req *mlbapi.GetThresholdsRequest) (*mlbapi.GetThresholdsResponse, error) {
+	thresholds := s.handler.GetThresholds()
+	return &mlbapi.GetThresholdsResponse{
+		OverloadThreshold: int32(thresholds.OverloadThreshold),
+		TargetThreshold:   int32(thresholds.TargetThreshold),
+	}, nil
+}
+
+func (s *Service) SetThresholds(ctx context.Context, req *mlbapi.SetThresholdsRequest) (*mlbapi.SetThresholdsResponse, error) {
+	err := s.handler.SetThresholds(ctx, controller.Thresholds{
+		OverloadThreshold: int(req.GetOverloadThreshold()),
+		TargetThreshold:   int(req.GetTargetThreshold()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &mlbapi.SetThresholdsResponse{}, nil
+}
+
+func (s *Service) StreamEvents(req *mlbapi.StreamEventsRequest, stream mlbapi.MlbService_StreamEventsServer) error {
+	id := uuid.New()
+	ch, err := s.handler.Watchers().AddWatcher(id, streamEventsBufferSize, watcher.PolicyDropOldest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.handler.Watchers().RemoveWatcher(id)
+	}()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			resp := &mlbapi.StreamEventsResponse{Type: toEventType(ev.Type)}
+			if ev.Type != event.ThresholdsChanged {
+				if sCell, ok := ev.Key.(storage.IDs); ok {
+					resp.SCell = toCellID(sCell)
+				}
+			}
+			if value, err := json.Marshal(ev.Value); err != nil {
+				log.Warnf("dropping payload for event %v: %s", ev.Type, err)
+			} else {
+				resp.Value = value
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func toEventType(t event.Type) mlbapi.EventType {
+	switch t {
+	case event.ThresholdsChanged:
+		return mlbapi.EventType_THRESHOLDS_CHANGED
+	case event.ThresholdBreached:
+		return mlbapi.EventType_THRESHOLD_BREACHED
+	default:
+		return mlbapi.EventType_OCN_CHANGED
+	}
+}
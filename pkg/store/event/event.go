@@ -0,0 +1,31 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+// Package event defines the value type carried over a watcher.Watchers
+// subscription.
+package event
+
+// Type distinguishes the kind of change an Event reports.
+type Type string
+
+const (
+	// OcnChanged is sent whenever an sCell's OCN towards a neighbor is
+	// written, whether by the control loop or an operator override.
+	OcnChanged Type = "OCN_CHANGED"
+
+	// ThresholdsChanged is sent whenever the overload/target thresholds are
+	// updated.
+	ThresholdsChanged Type = "THRESHOLDS_CHANGED"
+
+	// ThresholdBreached is sent whenever a cell's load crosses the overload
+	// or target threshold.
+	ThresholdBreached Type = "THRESHOLD_BREACHED"
+)
+
+// Event is one change notification fanned out by watcher.Watchers.
+type Event struct {
+	Type  Type
+	Key   interface{}
+	Value interface{}
+}
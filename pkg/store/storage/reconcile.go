@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package storage
+
+import "context"
+
+// PutVersioned writes value into store under key, tagging it with
+// CurrentOcnSchemaVersion, a revision one greater than whatever was
+// previously stored there (starting at 1), and kind, so a later
+// Snapshot/Restore round-trip knows how to decode it. Callers that always
+// need their write to stick - e.g. updateOcnStore reconciling a neighbor
+// list - should go through this instead of Store.Put directly.
+func PutVersioned(ctx context.Context, store Store, key IDs, kind Kind, value interface{}) error {
+	rev := uint64(1)
+	if entry, err := store.Get(ctx, key); err == nil {
+		if ve, ok := entry.Value.(VersionedEntry); ok {
+			rev = ve.Revision + 1
+		}
+	}
+	_, err := store.Put(ctx, key, VersionedEntry{
+		Version:  CurrentOcnSchemaVersion,
+		Revision: rev,
+		Kind:     kind,
+		Value:    value,
+	})
+	return err
+}
+
+// GetVersioned reads the value stored under key, migrating it up to
+// CurrentOcnSchemaVersion if it predates the running binary, and persists
+// the migrated result so later reads skip the migration.
+func GetVersioned(ctx context.Context, store Store, key IDs) (interface{}, error) {
+	entry, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ve, ok := entry.Value.(VersionedEntry)
+	if !ok {
+		// pre-existing, unversioned value; treat it as already current
+		return entry.Value, nil
+	}
+	if ve.Version == CurrentOcnSchemaVersion {
+		return ve.Value, nil
+	}
+
+	migrated, err := Migrate(ve)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.Put(ctx, key, migrated); err != nil {
+		return nil, err
+	}
+	return migrated.Value, nil
+}
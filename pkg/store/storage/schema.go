@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+)
+
+// SchemaVersion tags the shape of a value stored under an IDs key, so a
+// reader can tell whether a registered migration needs to run before the
+// value is safe to use.
+type SchemaVersion int
+
+// CurrentOcnSchemaVersion is the schema version this build writes. Bump it,
+// and register a migration from the previous version with
+// RegisterMigration, whenever the shape of a versioned value changes.
+const CurrentOcnSchemaVersion SchemaVersion = 1
+
+// VersionedEntry is what the reconciliation subsystem actually persists: a
+// caller's value tagged with the schema version it was written at, a
+// revision that increases on every write (so a reader can detect a write
+// that was silently dropped), and the Kind of its Value (see Kind).
+type VersionedEntry struct {
+	Version  SchemaVersion
+	Revision uint64
+	Kind     Kind
+	Value    interface{}
+}
+
+// Kind identifies the concrete Go type a VersionedEntry.Value holds.
+// Snapshot looks up a Value's Kind to encode it; Restore looks the same Kind
+// up to decode it back, since encoding/json has no way to infer a concrete
+// type from an interface{} alone.
+type Kind string
+
+// KindOcnMap tags a VersionedEntry whose Value is an OcnMap, as written by
+// pkg/controller's updateOcnStore/applyOcnDelta.
+const KindOcnMap Kind = "OcnMap"
+
+// EncodeFunc marshals a Kind's concrete Go value to the raw JSON Snapshot
+// writes for it (see ocnMapEntry for why this isn't always a plain
+// json.Marshal).
+type EncodeFunc func(value interface{}) ([]byte, error)
+
+// DecodeFunc reverses the EncodeFunc registered for the same Kind, turning
+// Restore's raw JSON back into that Kind's concrete Go type.
+type DecodeFunc func(data []byte) (interface{}, error)
+
+// codec pairs the Encode/Decode funcs Snapshot/Restore use for a Kind.
+type codec struct {
+	encode EncodeFunc
+	decode DecodeFunc
+}
+
+// codecs is keyed by Kind. RegisterKind adds to it, and Snapshot/Restore
+// consult it to (de)serialize a VersionedEntry.Value.
+var codecs = map[Kind]codec{
+	KindOcnMap: {encode: encodeOcnMap, decode: decodeOcnMap},
+}
+
+// ocnMapEntry is one (neighbor, OCN) pair of an OcnMap.Value, flattened to a
+// JSON-safe shape - IDs can't be a JSON object key, so Snapshot writes a
+// list of pairs instead of the map itself.
+type ocnMapEntry struct {
+	Neighbor IDs
+	Ocn      meastype.QOffsetRange
+}
+
+func encodeOcnMap(value interface{}) ([]byte, error) {
+	ocnMap, ok := value.(OcnMap)
+	if !ok {
+		return nil, fmt.Errorf("encode: expected OcnMap, got %T", value)
+	}
+	pairs := make([]ocnMapEntry, 0, len(ocnMap.Value))
+	for k, v := range ocnMap.Value {
+		pairs = append(pairs, ocnMapEntry{Neighbor: k, Ocn: v})
+	}
+	return json.Marshal(pairs)
+}
+
+func decodeOcnMap(data []byte) (interface{}, error) {
+	var pairs []ocnMapEntry
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, err
+	}
+	m := make(map[IDs]meastype.QOffsetRange, len(pairs))
+	for _, p := range pairs {
+		m[p.Neighbor] = p.Ocn
+	}
+	return OcnMap{Value: m}, nil
+}
+
+// RegisterKind teaches Snapshot/Restore how to (de)serialize a Kind whose Go
+// type lives outside this package - e.g. pkg/controller.Thresholds, which
+// would import storage if storage tried to reference it directly. Call it
+// from an init() alongside the type's definition. A plain json.Marshal/
+// json.Unmarshal pair is enough unless the type can't round-trip through
+// encoding/json as-is (see encodeOcnMap for why OcnMap needs more).
+func RegisterKind(kind Kind, encode EncodeFunc, decode DecodeFunc) {
+	codecs[kind] = codec{encode: encode, decode: decode}
+}
+
+// MigrationFunc upgrades a value stored at schema version `from` to the
+// shape expected at `from+1`.
+type MigrationFunc func(value interface{}) (interface{}, error)
+
+// migrations is keyed by the schema version a value is migrating *from*.
+var migrations = make(map[SchemaVersion]MigrationFunc)
+
+// RegisterMigration registers the function that upgrades a value stored at
+// schema version `from` to `from+1`. Call it from an init() alongside the
+// change that bumps CurrentOcnSchemaVersion.
+func RegisterMigration(from SchemaVersion, fn MigrationFunc) {
+	migrations[from] = fn
+}
+
+// Migrate runs every registered migration needed to bring entry up to
+// CurrentOcnSchemaVersion, in order.
+func Migrate(entry VersionedEntry) (VersionedEntry, error) {
+	for entry.Version < CurrentOcnSchemaVersion {
+		fn, ok := migrations[entry.Version]
+		if !ok {
+			return entry, fmt.Errorf("no migration registered from schema version %d", entry.Version)
+		}
+		value, err := fn(entry.Value)
+		if err != nil {
+			return entry, fmt.Errorf("migrating from schema version %d: %w", entry.Version, err)
+		}
+		entry.Value = value
+		entry.Version++
+	}
+	return entry, nil
+}
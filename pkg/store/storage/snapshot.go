@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// rawVersionedEntry mirrors VersionedEntry but keeps Value as raw JSON
+// instead of interface{}, so Restore can look Kind up in codecs (see Kind)
+// before decoding Value with the matching DecodeFunc.
+type rawVersionedEntry struct {
+	Version  SchemaVersion
+	Revision uint64
+	Kind     Kind
+	Value    json.RawMessage
+}
+
+// snapshotEntry is one key/value pair as serialized by Snapshot.
+type snapshotEntry struct {
+	Key   IDs
+	Value rawVersionedEntry
+}
+
+// Snapshot serializes every entry in store as JSON, so an operator can back
+// up the OCN matrix (or any other versioned store, e.g. thresholds) for
+// later Restore. Entries that predate the reconciliation subsystem are
+// wrapped at CurrentOcnSchemaVersion and tagged with legacyKind, since an
+// unversioned entry carries no Kind of its own for Restore to decode by.
+func Snapshot(ctx context.Context, store Store, legacyKind Kind) (io.Reader, error) {
+	ch := make(chan *Entry)
+	if err := store.ListElements(ctx, ch); err != nil {
+		return nil, err
+	}
+
+	entries := make([]snapshotEntry, 0)
+	for e := range ch {
+		ve, ok := e.Value.(VersionedEntry)
+		if !ok {
+			ve = VersionedEntry{Version: CurrentOcnSchemaVersion, Revision: 1, Kind: legacyKind, Value: e.Value}
+		}
+		c, ok := codecs[ve.Kind]
+		if !ok {
+			return nil, fmt.Errorf("snapshot: no codec registered for kind %q", ve.Kind)
+		}
+		rawValue, err := c.encode(ve.Value)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, snapshotEntry{
+			Key: e.Key,
+			Value: rawVersionedEntry{
+				Version:  ve.Version,
+				Revision: ve.Revision,
+				Kind:     ve.Kind,
+				Value:    rawValue,
+			},
+		})
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// Restore replays a Snapshot produced earlier into store. Each entry's raw
+// Value is decoded using the DecodeFunc registered for its Kind (see
+// RegisterKind) so it comes back as the concrete type it was written as,
+// then migrated up to CurrentOcnSchemaVersion if it predates the running
+// binary, before being written back.
+func Restore(ctx context.Context, store Store, r io.Reader) error {
+	var entries []snapshotEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		c, ok := codecs[e.Value.Kind]
+		if !ok {
+			return fmt.Errorf("restore: no codec registered for kind %q", e.Value.Kind)
+		}
+		value, err := c.decode(e.Value.Value)
+		if err != nil {
+			return fmt.Errorf("restore: decoding kind %q: %w", e.Value.Kind, err)
+		}
+
+		migrated, err := Migrate(VersionedEntry{
+			Version:  e.Value.Version,
+			Revision: e.Value.Revision,
+			Kind:     e.Value.Kind,
+			Value:    value,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := store.Put(ctx, e.Key, migrated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
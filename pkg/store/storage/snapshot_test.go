@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package storage
+
+import (
+	"context"
+	meastype "github.com/onosproject/rrm-son-lib/pkg/model/measurement/type"
+	"testing"
+)
+
+// TestSnapshotRestoreOcnMap asserts an OcnMap store survives a Snapshot/
+// Restore round-trip with its values and concrete type intact, rather than
+// coming back as a bare map[string]interface{}.
+func TestSnapshotRestoreOcnMap(t *testing.T) {
+	ctx := context.Background()
+	sCell := IDs{PlmnID: "1", CellID: "s0", NodeID: "node0"}
+	nCell := IDs{PlmnID: "1", CellID: "n0", NodeID: "nnode0"}
+
+	store := NewStore()
+	if err := PutVersioned(ctx, store, sCell, KindOcnMap, OcnMap{
+		Value: map[IDs]meastype.QOffsetRange{nCell: meastype.QOffset4dB},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Snapshot(ctx, store, KindOcnMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewStore()
+	if err := Restore(ctx, restored, r); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := GetVersioned(ctx, restored, sCell)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocnMap, ok := value.(OcnMap)
+	if !ok {
+		t.Fatalf("expected restored value to decode as OcnMap, got %T", value)
+	}
+	if got, want := ocnMap.Value[nCell], meastype.QOffset4dB; got != want {
+		t.Fatalf("expected restored OCN %v for %v, got %v", want, nCell, got)
+	}
+}
@@ -7,63 +7,162 @@ package watcher
 import (
 	"github.com/google/uuid"
 	"github.com/onosproject/onos-mlb/pkg/store/event"
+	"github.com/prometheus/client_golang/prometheus"
 	"sync"
 )
 
+// EventChannel is the type of channel a watcher receives events on.
 type EventChannel chan event.Event
 
-type Watchers struct {
-	watchers map[uuid.UUID]Watcher
-	rm sync.RWMutex
+// SlowConsumerPolicy controls what Send does when a watcher's buffer is
+// full.
+type SlowConsumerPolicy int
+
+const (
+	// PolicyDropOldest evicts the oldest buffered event to make room for the
+	// new one, so a slow watcher falls behind rather than stalling Send.
+	PolicyDropOldest SlowConsumerPolicy = iota
+	// PolicyDisconnect removes the watcher and closes its channel instead of
+	// dropping events silently.
+	PolicyDisconnect
+)
+
+// DefaultBufferSize is the per-watcher buffer used when AddWatcher is given
+// a bufferSize <= 0.
+const DefaultBufferSize = 32
+
+var (
+	eventsDelivered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "onos_mlb",
+		Subsystem: "watcher",
+		Name:      "events_delivered_total",
+		Help:      "Total number of events delivered to watchers.",
+	})
+	eventsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "onos_mlb",
+		Subsystem: "watcher",
+		Name:      "events_dropped_total",
+		Help:      "Total number of events dropped because a watcher's buffer was full.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(eventsDelivered, eventsDropped)
 }
 
+// Watcher is a single subscriber registered with Watchers.
 type Watcher struct {
-	id uuid.UUID
-	ch chan<- event.Event
+	id     uuid.UUID
+	ch     chan event.Event
+	policy SlowConsumerPolicy
+}
+
+// Watchers fans events out to every registered subscriber.
+type Watchers struct {
+	mu       sync.RWMutex
+	watchers map[uuid.UUID]*Watcher
 }
 
+// NewWatchers returns an empty set of watchers.
 func NewWatchers() *Watchers {
 	return &Watchers{
-		watchers: make(map[uuid.UUID]Watcher),
+		watchers: make(map[uuid.UUID]*Watcher),
 	}
 }
 
-// Send sends an event for all registered watchers
+// Send fans event out to every registered watcher. It never blocks on a slow
+// consumer: a watcher whose buffer is full is handled per its configured
+// SlowConsumerPolicy instead. The loop holds ws.mu for reading throughout, so
+// a concurrent RemoveWatcher/disconnect can't close a channel mid-send.
 func (ws *Watchers) Send(event event.Event) {
-	ws.rm.RLock()
-	go func() {
-		for _, watcher := range ws.watchers {
-			watcher.ch <- event
+	ws.mu.RLock()
+	toDisconnect := make([]*Watcher, 0)
+	for _, watcher := range ws.watchers {
+		if !ws.deliver(watcher, event) {
+			toDisconnect = append(toDisconnect, watcher)
 		}
-	}()
-	ws.rm.RUnlock()
+	}
+	ws.mu.RUnlock()
+
+	for _, watcher := range toDisconnect {
+		ws.disconnect(watcher)
+	}
 }
 
-// AddWatcher adds a watcher
-func (ws *Watchers) AddWatcher(id uuid.UUID, ch chan<- event.Event) error {
-	ws.rm.Lock()
-	watcher := Watcher{
-		id: id,
-		ch: ch,
+// deliver attempts a non-blocking send to watcher, applying its
+// SlowConsumerPolicy if the buffer is full. The caller must hold ws.mu. It
+// returns false if watcher should be disconnected.
+func (ws *Watchers) deliver(watcher *Watcher, event event.Event) bool {
+	select {
+	case watcher.ch <- event:
+		eventsDelivered.Inc()
+		return true
+	default:
+	}
+
+	if watcher.policy == PolicyDisconnect {
+		eventsDropped.Inc()
+		return false
 	}
+
+	// PolicyDropOldest: evict one buffered event, then retry once.
+	select {
+	case <-watcher.ch:
+		eventsDropped.Inc()
+	default:
+	}
+	select {
+	case watcher.ch <- event:
+		eventsDelivered.Inc()
+	default:
+		eventsDropped.Inc()
+	}
+	return true
+}
+
+// AddWatcher registers a new watcher with its own buffered channel and
+// returns it for the caller to range/select over. bufferSize <= 0 falls back
+// to DefaultBufferSize. policy controls what Send does once the buffer is
+// full.
+func (ws *Watchers) AddWatcher(id uuid.UUID, bufferSize int, policy SlowConsumerPolicy) (<-chan event.Event, error) {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	watcher := &Watcher{
+		id:     id,
+		ch:     make(chan event.Event, bufferSize),
+		policy: policy,
+	}
+
+	ws.mu.Lock()
 	ws.watchers[id] = watcher
-	ws.rm.Unlock()
-	return nil
+	ws.mu.Unlock()
 
+	return watcher.ch, nil
 }
 
-// RemoveWatcher removes a watcher
+// RemoveWatcher removes a watcher and closes its channel.
 func (ws *Watchers) RemoveWatcher(id uuid.UUID) error {
-	ws.rm.Lock()
-	watchers := make(map[uuid.UUID]Watcher, len(ws.watchers)-1)
-	for _, watcher := range ws.watchers {
-		if watcher.id != id {
-			watchers[id] = watcher
-
-		}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	watcher, ok := ws.watchers[id]
+	if !ok {
+		return nil
 	}
-	ws.watchers = watchers
-	ws.rm.Unlock()
+	delete(ws.watchers, id)
+	close(watcher.ch)
 	return nil
+}
 
+// disconnect removes watcher and closes its channel, guarding against a
+// concurrent RemoveWatcher/disconnect already having done so.
+func (ws *Watchers) disconnect(watcher *Watcher) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if current, ok := ws.watchers[watcher.id]; !ok || current != watcher {
+		return
+	}
+	delete(ws.watchers, watcher.id)
+	close(watcher.ch)
 }
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2020-present Open Networking Foundation <info@opennetworking.org>
+//
+// SPDX-License-Identifier: LicenseRef-ONF-Member-1.0
+
+package watcher
+
+import (
+	"github.com/google/uuid"
+	"github.com/onosproject/onos-mlb/pkg/store/event"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConcurrentAddRemoveSend exercises AddWatcher/RemoveWatcher/Send from
+// many goroutines at once. It asserts no panic (the race this guards
+// against is Send delivering on a channel RemoveWatcher has just closed),
+// no goroutine leak, and that every watcher ends up removed.
+func TestConcurrentAddRemoveSend(t *testing.T) {
+	ws := NewWatchers()
+	before := runtime.NumGoroutine()
+
+	const subscribers = 20
+	const sends = 500
+
+	var wg sync.WaitGroup
+	var delivered int64
+
+	for i := 0; i < subscribers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := uuid.New()
+			policy := PolicyDropOldest
+			if i%2 == 0 {
+				policy = PolicyDisconnect
+			}
+			ch, err := ws.AddWatcher(id, 4, policy)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			drainDone := make(chan struct{})
+			go func() {
+				defer close(drainDone)
+				for range ch {
+					atomic.AddInt64(&delivered, 1)
+				}
+			}()
+
+			time.Sleep(time.Millisecond)
+			_ = ws.RemoveWatcher(id)
+			<-drainDone
+		}(i)
+	}
+
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ws.Send(event.Event{Type: event.OcnChanged, Value: i})
+		}(i)
+	}
+
+	wg.Wait()
+
+	ws.mu.RLock()
+	remaining := len(ws.watchers)
+	ws.mu.RUnlock()
+	if remaining != 0 {
+		t.Fatalf("expected all watchers removed, got %d remaining", remaining)
+	}
+
+	// give the drain goroutines started above a moment to exit before
+	// checking for leaks
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+	if after > before+5 {
+		t.Fatalf("possible goroutine leak: before=%d after=%d", before, after)
+	}
+}
+
+// TestRemoveWatcherDeletesOnlyTargetID guards the "RemoveWatcher re-keys
+// every entry under the removed id" bug: removing one watcher must not
+// disturb any other watcher's registration.
+func TestRemoveWatcherDeletesOnlyTargetID(t *testing.T) {
+	ws := NewWatchers()
+	idA := uuid.New()
+	idB := uuid.New()
+
+	chA, err := ws.AddWatcher(idA, 1, PolicyDropOldest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ws.AddWatcher(idB, 1, PolicyDropOldest); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ws.RemoveWatcher(idA); err != nil {
+		t.Fatal(err)
+	}
+
+	ws.mu.RLock()
+	_, aRemoved := ws.watchers[idA]
+	_, bStillThere := ws.watchers[idB]
+	ws.mu.RUnlock()
+	if aRemoved {
+		t.Fatalf("idA should have been removed")
+	}
+	if !bStillThere {
+		t.Fatalf("idB should still be registered")
+	}
+
+	select {
+	case _, ok := <-chA:
+		if ok {
+			t.Fatalf("expected idA's channel to be closed, got an event instead")
+		}
+	default:
+		t.Fatalf("expected idA's channel to be closed and readable immediately")
+	}
+}